@@ -0,0 +1,44 @@
+// Command initca bootstraps the device CA: a self-signed certificate and a
+// password-encrypted private key, in the format cmd/issuecert and
+// cmd/revokecert expect. Run this once per deployment before issuing any
+// device certificates.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"rfid-backend/services"
+)
+
+func main() {
+	var (
+		commonName  = flag.String("common-name", "HackPGH RFID Device CA", "CA certificate common name")
+		validDays   = flag.Int("valid-days", 3650, "number of days the CA certificate is valid for")
+		certOutPath = flag.String("cert-out", "ca.crt", "path to write the CA certificate")
+		keyOutPath  = flag.String("key-out", "ca.key", "path to write the encrypted CA private key")
+	)
+	flag.Parse()
+
+	password := os.Getenv("RFID_CA_KEY_PASSWORD")
+	if password == "" {
+		log.Fatal("initca: RFID_CA_KEY_PASSWORD must be set")
+	}
+
+	validFor := time.Duration(*validDays) * 24 * time.Hour
+	certPEM, encryptedKeyPEM, err := services.GenerateCA(*commonName, validFor, password)
+	if err != nil {
+		log.Fatalf("initca: failed to generate CA: %v", err)
+	}
+
+	if err := os.WriteFile(*certOutPath, certPEM, 0644); err != nil {
+		log.Fatalf("initca: failed to write CA certificate: %v", err)
+	}
+	if err := os.WriteFile(*keyOutPath, encryptedKeyPEM, 0600); err != nil {
+		log.Fatalf("initca: failed to write CA key: %v", err)
+	}
+
+	log.Printf("initca: wrote %s and %s; distribute %s to the server as cfg.ClientCAFile", *certOutPath, *keyOutPath, *certOutPath)
+}
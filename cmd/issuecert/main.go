@@ -0,0 +1,73 @@
+// Command issuecert mints a client certificate for an RFID reader device
+// already known to the server via the registerDevice flow. It is meant to be
+// run by an operator on the same host as the server's SQLite database, using
+// the same CA bundle the server trusts for mTLS.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"rfid-backend/services"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	var (
+		caCertPath = flag.String("ca-cert", "ca.crt", "path to the CA certificate")
+		caKeyPath  = flag.String("ca-key", "ca.key", "path to the CA's encrypted private key")
+		dbPath     = flag.String("db", "rfid.db", "path to the server's SQLite database")
+		deviceName = flag.String("device", "", "registered device name (used as the certificate CN)")
+		validDays  = flag.Int("valid-days", 365, "number of days the issued certificate is valid for")
+	)
+	flag.Parse()
+
+	if *deviceName == "" {
+		log.Fatal("issuecert: -device is required")
+	}
+
+	password := os.Getenv("RFID_CA_KEY_PASSWORD")
+	if password == "" {
+		log.Fatal("issuecert: RFID_CA_KEY_PASSWORD must be set")
+	}
+
+	ca, err := services.LoadDeviceCA(*caCertPath, *caKeyPath, password)
+	if err != nil {
+		log.Fatalf("issuecert: failed to load CA: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", *dbPath)
+	if err != nil {
+		log.Fatalf("issuecert: failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	registry := services.NewDeviceRegistry(db)
+	registered, err := registry.IsRegistered(*deviceName)
+	if err != nil {
+		log.Fatalf("issuecert: failed to check device registration: %v", err)
+	}
+	if !registered {
+		log.Fatalf("issuecert: device %q is not registered; call /api/registerDevice first", *deviceName)
+	}
+
+	validFor := time.Duration(*validDays) * 24 * time.Hour
+	certPEM, keyPEM, serial, err := ca.IssueCertificate(*deviceName, validFor)
+	if err != nil {
+		log.Fatalf("issuecert: failed to issue certificate: %v", err)
+	}
+
+	if err := registry.RegisterDeviceCert(*deviceName, serial, time.Now().Add(validFor)); err != nil {
+		log.Fatalf("issuecert: failed to record issued certificate: %v", err)
+	}
+
+	// Printed once: the operator is responsible for delivering this to the
+	// device and not persisting a copy server-side.
+	fmt.Println(string(certPEM))
+	fmt.Println(string(keyPEM))
+}
@@ -0,0 +1,67 @@
+// Command revokecert marks a device's certificate revoked and regenerates
+// the CRL the server reloads on SIGHUP. It is meant to be run by an operator
+// on the same host as the server's SQLite database and CA bundle.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+
+	"rfid-backend/services"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	var (
+		caCertPath = flag.String("ca-cert", "ca.crt", "path to the CA certificate")
+		caKeyPath  = flag.String("ca-key", "ca.key", "path to the CA's encrypted private key")
+		dbPath     = flag.String("db", "rfid.db", "path to the server's SQLite database")
+		deviceName = flag.String("device", "", "registered device name to revoke")
+		crlOutPath = flag.String("crl-out", "revoked.crl", "path to write the signed CRL")
+	)
+	flag.Parse()
+
+	if *deviceName == "" {
+		log.Fatal("revokecert: -device is required")
+	}
+
+	password := os.Getenv("RFID_CA_KEY_PASSWORD")
+	if password == "" {
+		log.Fatal("revokecert: RFID_CA_KEY_PASSWORD must be set")
+	}
+
+	db, err := sql.Open("sqlite3", *dbPath)
+	if err != nil {
+		log.Fatalf("revokecert: failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	registry := services.NewDeviceRegistry(db)
+	if err := registry.RevokeDevice(*deviceName); err != nil {
+		log.Fatalf("revokecert: failed to revoke device: %v", err)
+	}
+
+	ca, err := services.LoadDeviceCA(*caCertPath, *caKeyPath, password)
+	if err != nil {
+		log.Fatalf("revokecert: failed to load CA: %v", err)
+	}
+
+	revoked, err := registry.RevokedCertificates()
+	if err != nil {
+		log.Fatalf("revokecert: failed to list revoked certificates: %v", err)
+	}
+
+	crl, err := ca.BuildCRL(revoked)
+	if err != nil {
+		log.Fatalf("revokecert: failed to build CRL: %v", err)
+	}
+
+	if err := os.WriteFile(*crlOutPath, crl, 0644); err != nil {
+		log.Fatalf("revokecert: failed to write CRL: %v", err)
+	}
+
+	log.Printf("revokecert: revoked %q; wrote CRL to %s (send the server SIGHUP to reload it)", *deviceName, *crlOutPath)
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rfid-backend/config"
+	"rfid-backend/models"
+	"rfid-backend/services"
+)
+
+// maxSnapshotHistory bounds the ring of prior cache snapshots kept in memory
+// so `since=<version>` delta requests work without unbounded growth.
+const maxSnapshotHistory = 20
+
+// cacheBundle is the signed, versioned payload a reader can cache and verify
+// offline, so a compromised network can't inject fake tag grants.
+type cacheBundle struct {
+	Version     int                 `json:"version"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Entries     []models.CacheEntry `json:"entries"`
+	Sig         string              `json:"sig,omitempty"`
+}
+
+// cacheDelta describes what changed between two snapshots of a cache.
+type cacheDelta struct {
+	Version int                 `json:"version"`
+	Added   []models.CacheEntry `json:"added"`
+	Removed []uint32            `json:"removed"`
+}
+
+type cacheSnapshot struct {
+	version int
+	etag    string
+	entries []models.CacheEntry
+}
+
+// CacheHandler serves the machine and door tag/training caches. Responses
+// carry a strong ETag over the sorted entry set so readers on flaky Wi-Fi can
+// poll with If-None-Match and get a 304 instead of redownloading the full
+// list. Callers can instead request a signed bundle (?signed=true) to verify
+// the response offline, or a delta since a prior version (?since=<version>).
+type CacheHandler struct {
+	dbService  *services.DBService
+	cfg        *config.Config
+	signingKey ed25519.PrivateKey
+
+	mu          sync.Mutex
+	history     map[string][]cacheSnapshot
+	nextVersion map[string]int
+}
+
+// NewCacheHandler validates cfg.CacheBundleSigningKey up front so a
+// misconfigured key fails startup instead of panicking the first time a
+// request asks for a signed bundle - ed25519.Sign panics on any key that
+// isn't exactly ed25519.PrivateKeySize bytes.
+func NewCacheHandler(dbService *services.DBService, cfg *config.Config) (*CacheHandler, error) {
+	if len(cfg.CacheBundleSigningKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("cache bundle signing key must be %d bytes, got %d", ed25519.PrivateKeySize, len(cfg.CacheBundleSigningKey))
+	}
+
+	return &CacheHandler{
+		dbService:   dbService,
+		cfg:         cfg,
+		signingKey:  ed25519.PrivateKey(cfg.CacheBundleSigningKey),
+		history:     make(map[string][]cacheSnapshot),
+		nextVersion: make(map[string]int),
+	}, nil
+}
+
+func (h *CacheHandler) HandleMachineCacheRequest() http.HandlerFunc {
+	return h.handleCacheRequest("machine", h.dbService.GetMachineCacheData)
+}
+
+func (h *CacheHandler) HandleDoorCacheRequest() http.HandlerFunc {
+	return h.handleCacheRequest("door", h.dbService.GetDoorCacheData)
+}
+
+func (h *CacheHandler) handleCacheRequest(cacheKey string, fetch func() ([]models.CacheEntry, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entries, err := fetch()
+		if err != nil {
+			http.Error(w, "failed to load cache", http.StatusInternalServerError)
+			return
+		}
+
+		sortEntries(entries)
+		etag := computeETag(entries)
+
+		if match := r.Header.Get("If-None-Match"); match != "" && match == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		snap := h.recordSnapshot(cacheKey, etag, entries)
+		w.Header().Set("ETag", etag)
+
+		if r.URL.Query().Get("signed") == "true" {
+			bundle, err := h.signBundle(snap)
+			if err != nil {
+				http.Error(w, "failed to sign cache bundle", http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, bundle)
+			return
+		}
+
+		if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+			since, err := strconv.Atoi(sinceParam)
+			if err != nil {
+				http.Error(w, "invalid since parameter", http.StatusBadRequest)
+				return
+			}
+
+			if delta, ok := h.deltaSince(cacheKey, since); ok {
+				writeJSON(w, delta)
+				return
+			}
+			// Requested version aged out of the ring buffer; fall back to a
+			// full snapshot below.
+		}
+
+		writeJSON(w, entries)
+	}
+}
+
+// recordSnapshot appends a new versioned snapshot unless the entry set is
+// unchanged from the last one recorded, in which case it returns that one.
+func (h *CacheHandler) recordSnapshot(cacheKey, etag string, entries []models.CacheEntry) cacheSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if existing := h.history[cacheKey]; len(existing) > 0 {
+		if last := existing[len(existing)-1]; last.etag == etag {
+			return last
+		}
+	}
+
+	h.nextVersion[cacheKey]++
+	snap := cacheSnapshot{version: h.nextVersion[cacheKey], etag: etag, entries: entries}
+
+	h.history[cacheKey] = append(h.history[cacheKey], snap)
+	if len(h.history[cacheKey]) > maxSnapshotHistory {
+		h.history[cacheKey] = h.history[cacheKey][len(h.history[cacheKey])-maxSnapshotHistory:]
+	}
+
+	return snap
+}
+
+func (h *CacheHandler) deltaSince(cacheKey string, since int) (*cacheDelta, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history := h.history[cacheKey]
+	if len(history) == 0 {
+		return nil, false
+	}
+
+	var sinceSnap *cacheSnapshot
+	for i := range history {
+		if history[i].version == since {
+			sinceSnap = &history[i]
+			break
+		}
+	}
+	if sinceSnap == nil {
+		return nil, false
+	}
+
+	return diffSnapshots(*sinceSnap, history[len(history)-1]), true
+}
+
+func diffSnapshots(from, to cacheSnapshot) *cacheDelta {
+	fromByTag := make(map[uint32]models.CacheEntry, len(from.entries))
+	for _, e := range from.entries {
+		fromByTag[e.TagId] = e
+	}
+
+	delta := &cacheDelta{Version: to.version}
+	seen := make(map[uint32]bool, len(to.entries))
+	for _, e := range to.entries {
+		seen[e.TagId] = true
+		if old, ok := fromByTag[e.TagId]; !ok || !trainingsEqual(old.Trainings, e.Trainings) {
+			delta.Added = append(delta.Added, e)
+		}
+	}
+	for tagId := range fromByTag {
+		if !seen[tagId] {
+			delta.Removed = append(delta.Removed, tagId)
+		}
+	}
+
+	return delta
+}
+
+func trainingsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// signBundle produces an Ed25519 signature over a canonical (Sig-less)
+// encoding of the bundle, so readers can verify it offline against a key
+// they already trust.
+func (h *CacheHandler) signBundle(snap cacheSnapshot) (*cacheBundle, error) {
+	bundle := &cacheBundle{
+		Version:     snap.version,
+		GeneratedAt: time.Now(),
+		Entries:     snap.entries,
+	}
+
+	canonical, err := json.Marshal(struct {
+		Version     int                 `json:"version"`
+		GeneratedAt time.Time           `json:"generated_at"`
+		Entries     []models.CacheEntry `json:"entries"`
+	}{bundle.Version, bundle.GeneratedAt, bundle.Entries})
+	if err != nil {
+		return nil, fmt.Errorf("failed to canonicalize cache bundle: %v", err)
+	}
+
+	bundle.Sig = base64.StdEncoding.EncodeToString(ed25519.Sign(h.signingKey, canonical))
+	return bundle, nil
+}
+
+func sortEntries(entries []models.CacheEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].TagId < entries[j].TagId })
+	for _, e := range entries {
+		sort.Strings(e.Trainings)
+	}
+}
+
+// computeETag is a strong ETag over the sorted (tagId, trainings) set.
+func computeETag(entries []models.CacheEntry) string {
+	sum := sha256.New()
+	for _, e := range entries {
+		fmt.Fprintf(sum, "%d:%s;", e.TagId, strings.Join(e.Trainings, ","))
+	}
+	return `"` + hex.EncodeToString(sum.Sum(nil)) + `"`
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"rfid-backend/config"
+	"rfid-backend/models"
+)
+
+func TestNewCacheHandlerRejectsShortSigningKey(t *testing.T) {
+	cfg := &config.Config{CacheBundleSigningKey: []byte("too-short")}
+
+	if _, err := NewCacheHandler(nil, cfg); err == nil {
+		t.Fatal("NewCacheHandler() with a short signing key: expected error, got nil")
+	}
+}
+
+func TestNewCacheHandlerAcceptsValidSigningKey(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+	cfg := &config.Config{CacheBundleSigningKey: priv}
+
+	if _, err := NewCacheHandler(nil, cfg); err != nil {
+		t.Fatalf("NewCacheHandler() error = %v, want nil for a valid-length key", err)
+	}
+}
+
+func TestComputeETagIsOrderIndependent(t *testing.T) {
+	a := []models.CacheEntry{
+		{TagId: 2, Trainings: []string{"laser"}},
+		{TagId: 1, Trainings: []string{"woodshop"}},
+	}
+	b := []models.CacheEntry{
+		{TagId: 1, Trainings: []string{"woodshop"}},
+		{TagId: 2, Trainings: []string{"laser"}},
+	}
+
+	sortEntries(a)
+	sortEntries(b)
+
+	if computeETag(a) != computeETag(b) {
+		t.Error("computeETag() differed for the same entries in a different starting order")
+	}
+}
+
+func TestComputeETagChangesWithContent(t *testing.T) {
+	a := []models.CacheEntry{{TagId: 1, Trainings: []string{"woodshop"}}}
+	b := []models.CacheEntry{{TagId: 1, Trainings: []string{"laser"}}}
+
+	if computeETag(a) == computeETag(b) {
+		t.Error("computeETag() was the same for entries with different trainings")
+	}
+}
+
+func TestDiffSnapshotsReportsAddedAndRemoved(t *testing.T) {
+	from := cacheSnapshot{
+		version: 1,
+		entries: []models.CacheEntry{
+			{TagId: 1, Trainings: []string{"woodshop"}},
+			{TagId: 2, Trainings: []string{"laser"}},
+		},
+	}
+	to := cacheSnapshot{
+		version: 2,
+		entries: []models.CacheEntry{
+			{TagId: 1, Trainings: []string{"woodshop", "3d-printer"}},
+			{TagId: 3, Trainings: []string{"cnc"}},
+		},
+	}
+
+	delta := diffSnapshots(from, to)
+
+	if delta.Version != 2 {
+		t.Errorf("delta.Version = %d, want 2", delta.Version)
+	}
+	if len(delta.Added) != 2 {
+		t.Fatalf("delta.Added = %v, want 2 entries (tag 1 changed, tag 3 new)", delta.Added)
+	}
+	if len(delta.Removed) != 1 || delta.Removed[0] != 2 {
+		t.Errorf("delta.Removed = %v, want [2]", delta.Removed)
+	}
+}
+
+func TestSignBundleProducesVerifiableSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+	h := &CacheHandler{signingKey: priv}
+
+	snap := cacheSnapshot{version: 1, entries: []models.CacheEntry{{TagId: 1, Trainings: []string{"woodshop"}}}}
+	bundle, err := h.signBundle(snap)
+	if err != nil {
+		t.Fatalf("signBundle() error = %v", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(bundle.Sig)
+	if err != nil {
+		t.Fatalf("failed to decode bundle signature: %v", err)
+	}
+
+	canonical, err := json.Marshal(struct {
+		Version     int                 `json:"version"`
+		GeneratedAt time.Time           `json:"generated_at"`
+		Entries     []models.CacheEntry `json:"entries"`
+	}{bundle.Version, bundle.GeneratedAt, bundle.Entries})
+	if err != nil {
+		t.Fatalf("failed to re-derive canonical bundle bytes: %v", err)
+	}
+
+	if !ed25519.Verify(pub, canonical, sig) {
+		t.Error("ed25519.Verify() = false for signBundle()'s own output")
+	}
+}
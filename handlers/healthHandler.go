@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthHandler backs /healthz and /readyz so ops tooling can alert when the
+// database is unreachable or the periodic membership sync has stalled.
+type HealthHandler struct {
+	db         *sql.DB
+	staleAfter time.Duration
+	mu         sync.RWMutex
+	lastSyncOK time.Time
+}
+
+func NewHealthHandler(db *sql.DB, staleAfter time.Duration) *HealthHandler {
+	return &HealthHandler{db: db, staleAfter: staleAfter}
+}
+
+// RecordSyncSuccess is called by the sync loop after it successfully
+// processes contacts, so Readyz can detect a stalled ticker.
+func (h *HealthHandler) RecordSyncSuccess(at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastSyncOK = at
+}
+
+// Healthz reports whether the process can reach its database.
+func (h *HealthHandler) Healthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.db.PingContext(r.Context()); err != nil {
+			http.Error(w, "database unreachable", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// Readyz additionally reports unready if the membership sync hasn't
+// succeeded within staleAfter, which catches a stalled 6-minute ticker.
+func (h *HealthHandler) Readyz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.db.PingContext(r.Context()); err != nil {
+			http.Error(w, "database unreachable", http.StatusServiceUnavailable)
+			return
+		}
+
+		h.mu.RLock()
+		lastSyncOK := h.lastSyncOK
+		h.mu.RUnlock()
+
+		if lastSyncOK.IsZero() {
+			http.Error(w, "no successful membership sync yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		if time.Since(lastSyncOK) > h.staleAfter {
+			http.Error(w, "membership sync is stale", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
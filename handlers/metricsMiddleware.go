@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"rfid-backend/metrics"
+)
+
+// InstrumentCacheEndpoint records request latency for a cache endpoint under
+// rfid_cache_request_duration_seconds{endpoint=...}.
+func InstrumentCacheEndpoint(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next(w, r)
+		metrics.CacheRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	}
+}
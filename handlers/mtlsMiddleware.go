@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"rfid-backend/metrics"
+	"rfid-backend/services"
+)
+
+// RequireRegisteredDevice wraps next so requests are only served if the
+// verified client certificate's CN matches a known, non-revoked device row.
+// The server's tls.Config sets ClientAuth to RequireAndVerifyClientCert, so
+// PeerCertificates is normally non-empty; a request with no TLS state or no
+// peer certificate is rejected with 401.
+//
+// Matching is CN-only, not CN-or-SAN: DeviceCA.IssueCertificate never sets
+// SANs on issued device certs, so a SAN lookup here would have nothing to
+// ever match against.
+func RequireRegisteredDevice(registry *services.DeviceRegistry, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+
+		cn := r.TLS.PeerCertificates[0].Subject.CommonName
+		registered, err := registry.IsRegistered(cn)
+		if err != nil {
+			slog.Error("failed to look up device", "device", cn, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if !registered {
+			http.Error(w, "unknown or revoked device", http.StatusForbidden)
+			return
+		}
+
+		metrics.DeviceLastSeen.WithLabelValues(cn).Set(float64(time.Now().Unix()))
+		next(w, r)
+	}
+}
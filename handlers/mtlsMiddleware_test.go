@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rfid-backend/services"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestDeviceRegistry(t *testing.T) *services.DeviceRegistry {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	const schema = `
+		CREATE TABLE devices (
+			name TEXT PRIMARY KEY,
+			cert_serial TEXT,
+			cert_not_after DATETIME,
+			revoked_at DATETIME
+		)`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create devices table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO devices (name) VALUES (?)`, "front-door"); err != nil {
+		t.Fatalf("failed to seed devices table: %v", err)
+	}
+
+	return services.NewDeviceRegistry(db)
+}
+
+func requestWithPeerCN(cn string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/machineCache", nil)
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: cn}}},
+	}
+	return req
+}
+
+func TestRequireRegisteredDeviceRejectsMissingClientCert(t *testing.T) {
+	registry := newTestDeviceRegistry(t)
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	req := httptest.NewRequest(http.MethodGet, "/api/machineCache", nil)
+	rec := httptest.NewRecorder()
+	RequireRegisteredDevice(registry, next)(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if called {
+		t.Error("next was called for a request with no client certificate")
+	}
+}
+
+func TestRequireRegisteredDeviceRejectsUnknownDevice(t *testing.T) {
+	registry := newTestDeviceRegistry(t)
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	rec := httptest.NewRecorder()
+	RequireRegisteredDevice(registry, next)(rec, requestWithPeerCN("unknown-device"))
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if called {
+		t.Error("next was called for an unregistered device")
+	}
+}
+
+func TestRequireRegisteredDeviceRejectsRevokedDevice(t *testing.T) {
+	registry := newTestDeviceRegistry(t)
+	if err := registry.RevokeDevice("front-door"); err != nil {
+		t.Fatalf("RevokeDevice() error = %v", err)
+	}
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	rec := httptest.NewRecorder()
+	RequireRegisteredDevice(registry, next)(rec, requestWithPeerCN("front-door"))
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if called {
+		t.Error("next was called for a revoked device")
+	}
+}
+
+func TestRequireRegisteredDeviceAllowsRegisteredDevice(t *testing.T) {
+	registry := newTestDeviceRegistry(t)
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	rec := httptest.NewRecorder()
+	RequireRegisteredDevice(registry, next)(rec, requestWithPeerCN("front-door"))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !called {
+		t.Error("next was not called for a registered, non-revoked device")
+	}
+}
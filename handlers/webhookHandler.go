@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"rfid-backend/config"
+	"rfid-backend/models"
+	"rfid-backend/services"
+)
+
+const (
+	webhookSignatureHeader = "X-WildApricot-Signature"
+	webhookReplayWindow    = 5 * time.Minute
+)
+
+// webhookEvent mirrors the subset of Wild Apricot's webhook payload we act on.
+// Wild Apricot fires one POST per event; Action distinguishes ContactModified,
+// MembershipEnabled, MembershipDisabled, and MembershipLevelChanged.
+type webhookEvent struct {
+	EventId    string    `json:"EventId"`
+	Action     string    `json:"Action"`
+	Timestamp  time.Time `json:"ExecutionDateTime"`
+	Parameters struct {
+		ContactId int `json:"ContactId"`
+	} `json:"Parameters"`
+}
+
+// WebhookHandler consumes Wild Apricot webhook callbacks and re-syncs just the
+// affected contact, rather than waiting on the periodic poll in main.go.
+type WebhookHandler struct {
+	dbService *services.DBService
+	waService *services.WildApricotService
+	cfg       *config.Config
+	mapper    models.ContactMapper
+
+	mu         sync.Mutex
+	seenEvents map[string]time.Time
+}
+
+// NewWebhookHandler builds a handler that re-syncs the contact a webhook
+// event names. mapper should always be the Wild Apricot field-value mapper,
+// since webhooks only ever originate from Wild Apricot regardless of which
+// services.MembershipProvider is configured for the periodic sync.
+func NewWebhookHandler(dbService *services.DBService, waService *services.WildApricotService, cfg *config.Config, mapper models.ContactMapper) *WebhookHandler {
+	return &WebhookHandler{
+		dbService:  dbService,
+		waService:  waService,
+		cfg:        cfg,
+		mapper:     mapper,
+		seenEvents: make(map[string]time.Time),
+	}
+}
+
+// HandleWebhook validates the shared token and HMAC signature on the raw
+// request body, then dispatches recognized events to a single-contact resync.
+func (h *WebhookHandler) HandleWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !constantTimeStringsEqual(r.URL.Query().Get("token"), h.cfg.WildApricotWebhookToken) {
+			http.Error(w, "invalid token", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		if !h.verifySignature(body, r.Header.Get(webhookSignatureHeader)) {
+			http.Error(w, "invalid signature", http.StatusBadRequest)
+			return
+		}
+
+		var event webhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		if event.EventId == "" || event.Parameters.ContactId == 0 {
+			http.Error(w, "missing event id or contact id", http.StatusBadRequest)
+			return
+		}
+
+		if h.isReplay(event) {
+			slog.Info("ignoring replayed webhook event", "event_id", event.EventId)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		switch event.Action {
+		case "ContactModified", "MembershipEnabled", "MembershipDisabled", "MembershipLevelChanged":
+			if err := h.resyncContact(event.Parameters.ContactId); err != nil {
+				slog.Error("failed to resync contact", "event_id", event.EventId, "contact_id", event.Parameters.ContactId, "error", err)
+				http.Error(w, "failed to resync contact", http.StatusInternalServerError)
+				return
+			}
+		default:
+			slog.Info("ignoring unhandled webhook action", "event_id", event.EventId, "action", event.Action)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *WebhookHandler) resyncContact(contactId int) error {
+	contact, err := h.waService.GetContact(h.cfg.WildApricotAccountId, contactId)
+	if err != nil {
+		return fmt.Errorf("failed to fetch contact %d from Wild Apricot: %v", contactId, err)
+	}
+
+	return h.dbService.ProcessContactsData([]models.Contact{*contact}, h.mapper)
+}
+
+// verifySignature accepts either the current or previous signing secret, so a
+// secret rotation doesn't reject in-flight webhooks signed under the old key.
+func (h *WebhookHandler) verifySignature(body []byte, signature string) bool {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	for _, secret := range h.activeSecrets() {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if hmac.Equal(mac.Sum(nil), decoded) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *WebhookHandler) activeSecrets() []string {
+	secrets := []string{h.cfg.WildApricotWebhookSecret}
+
+	if h.cfg.WildApricotWebhookSecretPrevious == "" {
+		return secrets
+	}
+
+	rotationDeadline := h.cfg.WildApricotWebhookSecretRotatedAt.Add(h.cfg.WildApricotWebhookSecretRotationWindow)
+	if time.Now().Before(rotationDeadline) {
+		secrets = append(secrets, h.cfg.WildApricotWebhookSecretPrevious)
+	}
+
+	return secrets
+}
+
+// isReplay reports whether EventId+Timestamp has already been processed
+// within the replay window, recording it if not. Stale entries are swept on
+// each call so the map can't grow unbounded.
+func (h *WebhookHandler) isReplay(event webhookEvent) bool {
+	key := fmt.Sprintf("%s|%d", event.EventId, event.Timestamp.Unix())
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for k, seenAt := range h.seenEvents {
+		if now.Sub(seenAt) > webhookReplayWindow {
+			delete(h.seenEvents, k)
+		}
+	}
+
+	if _, ok := h.seenEvents[key]; ok {
+		return true
+	}
+
+	h.seenEvents[key] = now
+	return false
+}
+
+func constantTimeStringsEqual(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
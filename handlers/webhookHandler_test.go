@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rfid-backend/config"
+)
+
+func signBody(t *testing.T, secret string, body []byte) string {
+	t.Helper()
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newTestWebhookHandler(cfg *config.Config) *WebhookHandler {
+	return &WebhookHandler{
+		cfg:        cfg,
+		seenEvents: make(map[string]time.Time),
+	}
+}
+
+func TestVerifySignatureAcceptsCurrentSecret(t *testing.T) {
+	cfg := &config.Config{WildApricotWebhookSecret: "current-secret"}
+	h := newTestWebhookHandler(cfg)
+
+	body := []byte(`{"EventId":"evt-1"}`)
+	if !h.verifySignature(body, signBody(t, "current-secret", body)) {
+		t.Error("verifySignature() = false for a body signed with the current secret, want true")
+	}
+}
+
+func TestVerifySignatureRejectsWrongSecret(t *testing.T) {
+	cfg := &config.Config{WildApricotWebhookSecret: "current-secret"}
+	h := newTestWebhookHandler(cfg)
+
+	body := []byte(`{"EventId":"evt-1"}`)
+	if h.verifySignature(body, signBody(t, "wrong-secret", body)) {
+		t.Error("verifySignature() = true for a body signed with an unknown secret, want false")
+	}
+}
+
+func TestVerifySignatureAcceptsPreviousSecretWithinRotationWindow(t *testing.T) {
+	cfg := &config.Config{
+		WildApricotWebhookSecret:               "current-secret",
+		WildApricotWebhookSecretPrevious:       "previous-secret",
+		WildApricotWebhookSecretRotatedAt:      time.Now(),
+		WildApricotWebhookSecretRotationWindow: time.Hour,
+	}
+	h := newTestWebhookHandler(cfg)
+
+	body := []byte(`{"EventId":"evt-1"}`)
+	if !h.verifySignature(body, signBody(t, "previous-secret", body)) {
+		t.Error("verifySignature() = false for a body signed with the previous secret inside the rotation window, want true")
+	}
+}
+
+func TestVerifySignatureRejectsPreviousSecretAfterRotationWindow(t *testing.T) {
+	cfg := &config.Config{
+		WildApricotWebhookSecret:              "current-secret",
+		WildApricotWebhookSecretPrevious:       "previous-secret",
+		WildApricotWebhookSecretRotatedAt:      time.Now().Add(-2 * time.Hour),
+		WildApricotWebhookSecretRotationWindow: time.Hour,
+	}
+	h := newTestWebhookHandler(cfg)
+
+	body := []byte(`{"EventId":"evt-1"}`)
+	if h.verifySignature(body, signBody(t, "previous-secret", body)) {
+		t.Error("verifySignature() = true for a body signed with the previous secret after the rotation window elapsed, want false")
+	}
+}
+
+func TestIsReplayRejectsDuplicateEvent(t *testing.T) {
+	h := newTestWebhookHandler(&config.Config{})
+	event := webhookEvent{EventId: "evt-1", Timestamp: time.Now()}
+
+	if h.isReplay(event) {
+		t.Fatal("isReplay() = true for the first time an event is seen, want false")
+	}
+	if !h.isReplay(event) {
+		t.Error("isReplay() = false for a repeated event, want true")
+	}
+}
+
+func TestHandleWebhookRejectsInvalidToken(t *testing.T) {
+	cfg := &config.Config{WildApricotWebhookToken: "expected-token", WildApricotWebhookSecret: "secret"}
+	h := newTestWebhookHandler(cfg)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks?token=wrong-token", nil)
+	rec := httptest.NewRecorder()
+	h.HandleWebhook()(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleWebhookRejectsInvalidSignature(t *testing.T) {
+	cfg := &config.Config{WildApricotWebhookToken: "tok", WildApricotWebhookSecret: "secret"}
+	h := newTestWebhookHandler(cfg)
+
+	body, _ := json.Marshal(webhookEvent{EventId: "evt-1", Action: "SomethingUnhandled"})
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks?token=tok", bytes.NewReader(body))
+	req.Header.Set(webhookSignatureHeader, hex.EncodeToString([]byte("not-a-real-signature")))
+	rec := httptest.NewRecorder()
+	h.HandleWebhook()(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleWebhookAcceptsValidSignatureForUnhandledAction(t *testing.T) {
+	cfg := &config.Config{WildApricotWebhookToken: "tok", WildApricotWebhookSecret: "secret"}
+	h := newTestWebhookHandler(cfg)
+
+	event := webhookEvent{EventId: "evt-1", Action: "SomethingUnhandled"}
+	event.Parameters.ContactId = 42
+	body, _ := json.Marshal(event)
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks?token=tok", bytes.NewReader(body))
+	req.Header.Set(webhookSignatureHeader, signBody(t, "secret", body))
+	rec := httptest.NewRecorder()
+	h.HandleWebhook()(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
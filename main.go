@@ -17,16 +17,24 @@ Project Structure:
 
 Main Functionality:
 - Initializes the SQLite database using the specified database path from `config.yml`.
-- Sets up the Wild Apricot service for API interactions, enabling the retrieval of contact data.
+- Sets up a services.MembershipProvider (Wild Apricot, LDAP, or CSV, chosen via `config.yml`) for retrieving contact data.
 - Creates a DBService instance for handling database operations.
 - Initializes a CacheHandler with the DBService and configuration settings to handle HTTP requests.
 - Registers HTTP endpoints `/api/machineCache` and `/api/doorCache` for fetching RFID data
-  related to machines and door access.
+  related to machines and door access, requiring a client certificate mapped to a
+  registered device (see handlers.RequireRegisteredDevice).
 - Starts a background routine that periodically fetches contact data from the Wild Apricot
   API and updates the local SQLite database. This ensures the database is regularly
   synchronized with the latest data from Wild Apricot.
 - Launches an HTTPS server on port 443 to listen for incoming requests, using the SSL
-  certificate and key specified in the `config.yml`.
+  certificate and key specified in the `config.yml`, requiring and verifying client
+  certificates against a CA bundle that is reloaded on SIGHUP.
+- Launches a second, unauthenticated HTTP listener (`cfg.MetricsAddr`) exposing
+  `/metrics`, `/healthz`, and `/readyz` for ops tooling.
+- Launches a third HTTPS listener (`cfg.WebhookAddr`) for `/api/webhooks`, using
+  the same server certificate as :443 but without requiring a client
+  certificate, since Wild Apricot can never present one signed by this
+  deployment's private device CA.
 
 Usage:
 - Before running, ensure that the `config.yml` is properly set up with the necessary configuration, including database path, Wild Apricot account ID, SSL certificate, and key file locations.
@@ -36,15 +44,27 @@ Usage:
 package main
 
 import (
-	"log"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"rfid-backend/config"
 	"rfid-backend/db"
 	"rfid-backend/handlers"
+	"rfid-backend/metrics"
+	"rfid-backend/models"
 	"rfid-backend/services"
 	"runtime"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func getCurrentDirectory() string {
@@ -53,51 +73,281 @@ func getCurrentDirectory() string {
 }
 
 func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
 	cfg := config.LoadConfig()
 
 	database, err := db.InitDB(cfg.DatabasePath)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		slog.Error("failed to initialize database", "error", err)
+		os.Exit(1)
 	}
 	defer database.Close()
 
 	wildApricotSvc := services.NewWildApricotService(database)
 	dbService := services.NewDBService(database, cfg)
+	deviceRegistry := services.NewDeviceRegistry(database)
+	healthHandler := handlers.NewHealthHandler(database, 2*6*time.Minute)
+
+	membershipProvider, contactMapper, err := services.NewMembershipProvider(cfg, wildApricotSvc)
+	if err != nil {
+		slog.Error("failed to configure membership provider", "error", err)
+		os.Exit(1)
+	}
 
-	cacheHandler := handlers.NewCacheHandler(dbService, cfg)
+	cacheHandler, err := handlers.NewCacheHandler(dbService, cfg)
+	if err != nil {
+		slog.Error("failed to configure cache handler", "error", err)
+		os.Exit(1)
+	}
+
+	// Webhooks only ever originate from Wild Apricot, regardless of which
+	// services.MembershipProvider is configured for the periodic sync, so the
+	// resync path always uses the Wild Apricot field-value mapper.
+	waMapper := &models.FieldValueMapper{TagIdFieldName: cfg.TagIdFieldName, TrainingFieldName: cfg.TrainingFieldName}
+	webhookHandler := handlers.NewWebhookHandler(dbService, wildApricotSvc, cfg, waMapper)
 
-	http.HandleFunc("/api/machineCache", cacheHandler.HandleMachineCacheRequest())
-	http.HandleFunc("/api/doorCache", cacheHandler.HandleDoorCacheRequest())
+	apiMux := http.NewServeMux()
+	apiMux.HandleFunc("/api/machineCache", handlers.RequireRegisteredDevice(deviceRegistry, handlers.InstrumentCacheEndpoint("machineCache", cacheHandler.HandleMachineCacheRequest())))
+	apiMux.HandleFunc("/api/doorCache", handlers.RequireRegisteredDevice(deviceRegistry, handlers.InstrumentCacheEndpoint("doorCache", cacheHandler.HandleDoorCacheRequest())))
+
+	webhookMux := http.NewServeMux()
+	webhookMux.HandleFunc("/api/webhooks", webhookHandler.HandleWebhook())
 
 	// Start background task to fetch contacts and update the database
 	go func() {
 		ticker := time.NewTicker(6 * time.Minute)
 		for range ticker.C {
-			updateDatabaseFromWildApricot(wildApricotSvc, dbService, cfg.WildApricotAccountId)
+			updateDatabaseFromMembershipProvider(membershipProvider, dbService, contactMapper, healthHandler)
 		}
 	}()
 
-	log.Println("Starting HTTPS server on :443...")
-	log.Printf("certfile: %+v\n", cfg.CertFile)
-	log.Printf("keyfile: %+v\n", cfg.KeyFile)
-	err = http.ListenAndServeTLS(":443", cfg.CertFile, cfg.KeyFile, nil)
+	go startMetricsServer(cfg.MetricsAddr, healthHandler)
+
+	clientCAPool, err := newClientCertPool(cfg.ClientCAFile)
+	if err != nil {
+		slog.Error("failed to load client CA bundle", "error", err)
+		os.Exit(1)
+	}
+
+	serverCert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		slog.Error("failed to load server certificate", "error", err)
+		os.Exit(1)
+	}
+
+	revokedSerials, err := loadRevokedSerials(cfg.ClientCRLFile)
+	if err != nil {
+		slog.Error("failed to load client CRL", "error", err)
+		os.Exit(1)
+	}
+
+	tlsConfig := &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert}
+	reloader := newClientCAReloader(clientCAPool, serverCert, revokedSerials)
+	tlsConfig.GetConfigForClient = reloader.getConfigForClient
+
+	go reloadClientCAOnSIGHUP(reloader, cfg.ClientCAFile, cfg.ClientCRLFile)
+
+	// Wild Apricot can never present a certificate signed by this
+	// deployment's private device CA, so /api/webhooks is served off its own
+	// listener that doesn't set ClientAuth: RequireAndVerifyClientCert -
+	// sharing :443's tlsConfig would fail the TLS handshake for every
+	// webhook POST before it ever reached WebhookHandler.
+	go startWebhookServer(cfg.WebhookAddr, serverCert, webhookMux)
+
+	server := &http.Server{
+		Addr:      ":443",
+		TLSConfig: tlsConfig,
+		Handler:   apiMux,
+	}
+
+	slog.Info("starting HTTPS server", "addr", ":443", "certfile", cfg.CertFile, "keyfile", cfg.KeyFile)
+	err = server.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
 	if err != nil {
-		log.Fatalf("Failed to start HTTPS server: %v", err)
+		slog.Error("failed to start HTTPS server", "error", err)
+		os.Exit(1)
+	}
+}
+
+// startWebhookServer runs a separate HTTPS listener for the Wild Apricot
+// webhook endpoint, using the same server certificate as :443 but without
+// requiring a client certificate.
+func startWebhookServer(addr string, serverCert tls.Certificate, mux *http.ServeMux) {
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   mux,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{serverCert}},
+	}
+
+	slog.Info("starting webhook server", "addr", addr)
+	if err := server.ListenAndServeTLS("", ""); err != nil {
+		slog.Error("webhook server failed", "error", err)
 	}
 }
 
-func updateDatabaseFromWildApricot(waService *services.WildApricotService, dbService *services.DBService, accountId int) {
-	log.Println("Fetching contacts from Wild Apricot and updating database...")
-	contacts, err := waService.GetContacts(accountId)
+// startMetricsServer runs a second, unauthenticated HTTP listener for
+// Prometheus scraping and liveness/readiness probes, kept off :443 so it
+// doesn't need a client certificate.
+func startMetricsServer(addr string, healthHandler *handlers.HealthHandler) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", healthHandler.Healthz())
+	mux.HandleFunc("/readyz", healthHandler.Readyz())
+
+	slog.Info("starting metrics server", "addr", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		slog.Error("metrics server failed", "error", err)
+	}
+}
+
+// clientCAReloader holds the current client-certificate trust pool and CRL
+// and hands out a fresh *tls.Config per handshake, so a SIGHUP-triggered
+// reload takes effect for new connections without dropping ones already
+// established.
+//
+// GetConfigForClient replaces the server's tls.Config wholesale for that
+// handshake (see crypto/tls/handshake_server.go), so every config returned
+// here must also carry the server's own certificate — otherwise the
+// handshake has nothing to present and every connection to :443 fails, not
+// just mTLS ones.
+type clientCAReloader struct {
+	mu             sync.RWMutex
+	pool           *x509.CertPool
+	serverCert     tls.Certificate
+	revokedSerials map[string]struct{}
+}
+
+func newClientCAReloader(pool *x509.CertPool, serverCert tls.Certificate, revokedSerials map[string]struct{}) *clientCAReloader {
+	return &clientCAReloader{pool: pool, serverCert: serverCert, revokedSerials: revokedSerials}
+}
+
+func (r *clientCAReloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	revokedSerials := r.revokedSerials
+
+	return &tls.Config{
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    r.pool,
+		Certificates: []tls.Certificate{r.serverCert},
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("no client certificate presented")
+			}
+
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return fmt.Errorf("failed to parse client certificate: %v", err)
+			}
+
+			if _, revoked := revokedSerials[cert.SerialNumber.String()]; revoked {
+				return fmt.Errorf("client certificate %s is revoked", cert.SerialNumber.String())
+			}
+
+			return nil
+		},
+	}, nil
+}
+
+func (r *clientCAReloader) replace(pool *x509.CertPool, revokedSerials map[string]struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pool = pool
+	r.revokedSerials = revokedSerials
+}
+
+func newClientCertPool(caFile string) (*x509.CertPool, error) {
+	caBytes, err := os.ReadFile(caFile)
 	if err != nil {
-		log.Printf("Failed to fetch contacts: %v", err)
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBytes) {
+		return nil, os.ErrInvalid
+	}
+
+	return pool, nil
+}
+
+// loadRevokedSerials reads a DER-encoded CRL (produced by cmd/revokecert)
+// and returns the set of revoked certificate serial numbers. An empty
+// crlFile means no device has been revoked yet.
+func loadRevokedSerials(crlFile string) (map[string]struct{}, error) {
+	if crlFile == "" {
+		return map[string]struct{}{}, nil
+	}
+
+	derBytes, err := os.ReadFile(crlFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]struct{}{}, nil
+		}
+		return nil, err
+	}
+
+	crl, err := x509.ParseRevocationList(derBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CRL: %v", err)
+	}
+
+	revokedSerials := make(map[string]struct{}, len(crl.RevokedCertificateEntries))
+	for _, entry := range crl.RevokedCertificateEntries {
+		revokedSerials[entry.SerialNumber.String()] = struct{}{}
+	}
+
+	return revokedSerials, nil
+}
+
+// reloadClientCAOnSIGHUP rebuilds the client CA trust pool and the revoked-
+// serial set from the CRL written by cmd/revokecert whenever the process
+// receives SIGHUP.
+func reloadClientCAOnSIGHUP(reloader *clientCAReloader, caFile, crlFile string) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+
+	for range sigs {
+		pool, err := newClientCertPool(caFile)
+		if err != nil {
+			slog.Error("failed to reload client CA bundle", "error", err)
+			continue
+		}
+
+		revokedSerials, err := loadRevokedSerials(crlFile)
+		if err != nil {
+			slog.Error("failed to reload client CRL", "error", err)
+			continue
+		}
+
+		reloader.replace(pool, revokedSerials)
+		slog.Info("reloaded client CA bundle and CRL")
+	}
+}
+
+func updateDatabaseFromMembershipProvider(provider services.MembershipProvider, dbService *services.DBService, mapper models.ContactMapper, healthHandler *handlers.HealthHandler) {
+	slog.Info("fetching contacts from membership provider")
+	start := time.Now()
+
+	contacts, err := provider.GetContacts(context.Background())
+	if err != nil {
+		metrics.SyncFailures.Inc()
+		slog.Error("failed to fetch contacts", "error", err)
 		return
 	}
 
-	if err = dbService.ProcessContactsData(contacts); err != nil {
-		log.Printf("Failed to update database: %v", err)
+	// dbService.ProcessContactsData extracts tag IDs and training labels via
+	// Contact.ExtractContactData(mapper) for each contact, so it stays
+	// provider-agnostic regardless of which MembershipProvider supplied them.
+	if err = dbService.ProcessContactsData(contacts, mapper); err != nil {
+		metrics.SyncFailures.Inc()
+		slog.Error("failed to update database", "error", err)
 		return
 	}
 
-	log.Println("Database successfully updated with latest WA contact data.")
+	metrics.SyncDuration.Observe(time.Since(start).Seconds())
+	metrics.ContactsProcessed.Add(float64(len(contacts)))
+	healthHandler.RecordSyncSuccess(time.Now())
+
+	slog.Info("database successfully updated with latest contact data", "contacts_processed", len(contacts))
 }
@@ -0,0 +1,41 @@
+// Package metrics defines the Prometheus collectors exposed on /metrics.
+// It has no dependencies on the rest of the tree so any package (models,
+// services, handlers) can record against it without import cycles.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	SyncDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "rfid_wa_sync_duration_seconds",
+		Help: "Duration of a full membership-provider sync, in seconds.",
+	})
+
+	SyncFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rfid_wa_sync_failures_total",
+		Help: "Total number of failed membership-provider syncs.",
+	})
+
+	ContactsProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rfid_contacts_processed_total",
+		Help: "Total number of contacts processed into the local database.",
+	})
+
+	TagIdParseErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rfid_tagid_parse_errors_total",
+		Help: "Total number of TagId/training-label parse failures, by reason.",
+	}, []string{"reason"})
+
+	CacheRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "rfid_cache_request_duration_seconds",
+		Help: "Duration of /api/machineCache and /api/doorCache requests, in seconds.",
+	}, []string{"endpoint"})
+
+	DeviceLastSeen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rfid_device_last_seen_timestamp",
+		Help: "Unix timestamp of the last successfully authenticated request from a device.",
+	}, []string{"device"})
+)
@@ -0,0 +1,8 @@
+package models
+
+// CacheEntry is a single RFID tag grant served to door/machine readers: the
+// tag ID plus the safety trainings required to unlock it.
+type CacheEntry struct {
+	TagId     uint32   `json:"tagId"`
+	Trainings []string `json:"trainings"`
+}
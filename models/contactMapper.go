@@ -0,0 +1,37 @@
+package models
+
+// ContactMapper extracts the RFID tag ID and safety-training labels from a
+// Contact. Each membership provider (Wild Apricot, LDAP, CSV) supplies the
+// mapper matching how it populates Contact, so dbService.ProcessContactsData
+// can treat every provider's contacts identically.
+type ContactMapper interface {
+	ExtractTagID(c *Contact) (uint32, error)
+	ExtractTrainingLabels(c *Contact) ([]string, error)
+}
+
+// FieldValueMapper implements ContactMapper by looking up configured field
+// names in Contact.FieldValues. This is the shape Wild Apricot contacts
+// arrive in natively, and the shape the CSV/HTTP-pull provider normalizes
+// its rows into.
+type FieldValueMapper struct {
+	TagIdFieldName    string
+	TrainingFieldName string
+}
+
+func (m *FieldValueMapper) ExtractTagID(c *Contact) (uint32, error) {
+	for _, val := range c.FieldValues {
+		if val.FieldName == m.TagIdFieldName {
+			return parseTagId(val)
+		}
+	}
+	return 0, nil // Return 0 if TagId field is not found
+}
+
+func (m *FieldValueMapper) ExtractTrainingLabels(c *Contact) ([]string, error) {
+	for _, val := range c.FieldValues {
+		if val.FieldName == m.TrainingFieldName {
+			return parseTrainingLabels(val)
+		}
+	}
+	return nil, nil // Return nil if Training field is not found
+}
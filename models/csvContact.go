@@ -0,0 +1,55 @@
+package models
+
+import "strings"
+
+// NewContactFromCSVRow builds a Contact from a CSV row, shaping FieldValues
+// so the same FieldValueMapper used for Wild Apricot can extract tag IDs and
+// training labels from it. The training cell is a comma-separated list of
+// labels.
+//
+// csv.Reader.ReadAll() doesn't guarantee row order is stable between
+// fetches, so Contact.Id is derived from idColumn rather than the row's
+// position. idColumn must hold something that durably identifies the
+// person (e.g. a member ID or email), not the tag ID cell: a blank or
+// not-yet-issued tag is legitimately shared across many rows, and hashing
+// it would collide two different people onto the same Contact.Id. The
+// second return value is false if idColumn has no value for this row, in
+// which case the row can't be safely synced and should be skipped.
+func NewContactFromCSVRow(header, row []string, idColumn, tagIdColumn, trainingColumn string) (Contact, bool) {
+	var contact Contact
+	var idValue string
+
+	for i, column := range header {
+		if i >= len(row) {
+			continue
+		}
+
+		switch column {
+		case idColumn:
+			idValue = row[i]
+		case tagIdColumn:
+			contact.FieldValues = append(contact.FieldValues, FieldValue{FieldName: column, Value: row[i]})
+		case trainingColumn:
+			contact.FieldValues = append(contact.FieldValues, FieldValue{FieldName: column, Value: trainingLabelsToFieldValue(row[i])})
+		}
+	}
+
+	if idValue == "" {
+		return Contact{}, false
+	}
+
+	contact.Id = stableContactId(idValue)
+	return contact, true
+}
+
+func trainingLabelsToFieldValue(cell string) []interface{} {
+	var labels []interface{}
+	for _, label := range strings.Split(cell, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		labels = append(labels, map[string]interface{}{"Label": label})
+	}
+	return labels
+}
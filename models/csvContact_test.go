@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestNewContactFromCSVRowDistinctIdsForBlankTag(t *testing.T) {
+	header := []string{"MemberId", "TagId", "Trainings"}
+
+	alice, ok := NewContactFromCSVRow(header, []string{"alice@example.com", "", ""}, "MemberId", "TagId", "Trainings")
+	if !ok {
+		t.Fatal("NewContactFromCSVRow() ok = false for a row with an id column value, want true")
+	}
+
+	bob, ok := NewContactFromCSVRow(header, []string{"bob@example.com", "", ""}, "MemberId", "TagId", "Trainings")
+	if !ok {
+		t.Fatal("NewContactFromCSVRow() ok = false for a row with an id column value, want true")
+	}
+
+	if alice.Id == bob.Id {
+		t.Errorf("two different members with blank tag cells got the same Contact.Id (%d)", alice.Id)
+	}
+}
+
+func TestNewContactFromCSVRowSkipsRowWithNoIdValue(t *testing.T) {
+	header := []string{"MemberId", "TagId", "Trainings"}
+
+	_, ok := NewContactFromCSVRow(header, []string{"", "12345", ""}, "MemberId", "TagId", "Trainings")
+	if ok {
+		t.Error("NewContactFromCSVRow() ok = true for a row with no id column value, want false")
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+// NewContactFromLDAPEntry builds a Contact from a directory entry, storing
+// the configured tag and training-group attributes as FieldValues so
+// LDAPMapper can extract them the same way FieldValueMapper extracts Wild
+// Apricot's native fields.
+//
+// LDAP search result order isn't guaranteed stable between polls, so
+// Contact.Id is derived from entryUUID, the directory's own stable
+// identifier for the entry, falling back to its DN if entryUUID isn't
+// returned by the server.
+func NewContactFromLDAPEntry(entryUUID, dn, cn, mail, tagAttribute, tagValue, trainingAttribute string, trainingGroups []string) Contact {
+	idKey := entryUUID
+	if idKey == "" {
+		idKey = dn
+	}
+	contact := Contact{Id: stableContactId(idKey), DisplayName: cn, Email: mail}
+
+	contact.FieldValues = append(contact.FieldValues, FieldValue{FieldName: tagAttribute, Value: tagValue})
+
+	groups := make([]interface{}, len(trainingGroups))
+	for i, g := range trainingGroups {
+		groups[i] = g
+	}
+	contact.FieldValues = append(contact.FieldValues, FieldValue{FieldName: trainingAttribute, Value: groups})
+
+	return contact
+}
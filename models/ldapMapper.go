@@ -0,0 +1,48 @@
+package models
+
+import "errors"
+
+// LDAPMapper implements ContactMapper for contacts synthesized from
+// directory entries (see NewContactFromLDAPEntry). The tag ID comes from a
+// single-valued attribute (e.g. employeeNumber); training labels come from a
+// multi-valued group-membership attribute mapped onto human-readable labels.
+type LDAPMapper struct {
+	TagAttribute        string
+	TrainingAttribute   string
+	TrainingGroupLabels map[string]string // group DN/CN -> training label
+}
+
+func (m *LDAPMapper) ExtractTagID(c *Contact) (uint32, error) {
+	for _, val := range c.FieldValues {
+		if val.FieldName == m.TagAttribute {
+			return parseTagId(val)
+		}
+	}
+	return 0, nil
+}
+
+func (m *LDAPMapper) ExtractTrainingLabels(c *Contact) ([]string, error) {
+	for _, val := range c.FieldValues {
+		if val.FieldName != m.TrainingAttribute {
+			continue
+		}
+
+		groups, ok := val.Value.([]interface{})
+		if !ok {
+			return nil, errors.New("training attribute value is not a slice")
+		}
+
+		var labels []string
+		for _, g := range groups {
+			group, ok := g.(string)
+			if !ok {
+				return nil, errors.New("training group value is not a string")
+			}
+			if label, ok := m.TrainingGroupLabels[group]; ok {
+				labels = append(labels, label)
+			}
+		}
+		return labels, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,14 @@
+package models
+
+import "hash/fnv"
+
+// stableContactId derives a Contact.Id from a source-provided key that's
+// expected to stay constant for the same person across syncs (e.g. a member
+// ID, an email address, or a directory entryUUID/DN), rather than a row's position in a result set,
+// which isn't guaranteed stable between polls. The low 31 bits of an FNV-1a
+// hash keep the result a positive int while remaining deterministic.
+func stableContactId(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() & 0x7fffffff)
+}
@@ -3,8 +3,9 @@ package models
 import (
 	"errors"
 	"fmt"
-	"rfid-backend/config"
 	"strconv"
+
+	"rfid-backend/metrics"
 )
 
 // Contact represents the structure of a contact in the Wild Apricot API's /Contacts response.
@@ -36,34 +37,17 @@ type SafetyTraining struct {
 	Label string `json:"Label"`
 }
 
-// Returns contact_id, tagId, trainings
-func (c *Contact) ExtractTagID(cfg *config.Config) (uint32, error) {
-	for _, val := range c.FieldValues {
-		if val.FieldName == cfg.TagIdFieldName {
-			return parseTagId(val)
-		}
-	}
-	return 0, nil // Return 0 if TagId field is not found
-}
-
-// Extracts training labels from contact field values.
-func (c *Contact) ExtractTrainingLabels(cfg *config.Config) ([]string, error) {
-	for _, val := range c.FieldValues {
-		if val.FieldName == cfg.TrainingFieldName {
-			return parseTrainingLabels(val)
-		}
-	}
-	return nil, nil // Return nil if Training field is not found
-}
-
-// Combines extraction of Tag ID and Training Labels.
-func (c *Contact) ExtractContactData(cfg *config.Config) (int, uint32, []string, error) {
-	tagID, err := c.ExtractTagID(cfg)
+// Combines extraction of Tag ID and Training Labels using the ContactMapper
+// supplied by the active membership provider (Wild Apricot, LDAP, CSV), so
+// this stays identical for every provider downstream in
+// dbService.ProcessContactsData.
+func (c *Contact) ExtractContactData(mapper ContactMapper) (int, uint32, []string, error) {
+	tagID, err := mapper.ExtractTagID(c)
 	if err != nil {
 		return 0, 0, nil, fmt.Errorf("error extracting TagId for contact %d: %v", c.Id, err)
 	}
 
-	trainingLabels, err := c.ExtractTrainingLabels(cfg)
+	trainingLabels, err := mapper.ExtractTrainingLabels(c)
 	if err != nil {
 		err = fmt.Errorf("error extracting training labels for contact %d: %v", c.Id, err)
 	}
@@ -79,6 +63,7 @@ func parseTagId(fieldValue FieldValue) (uint32, error) {
 
 	strVal, ok := fieldValue.Value.(string)
 	if !ok {
+		metrics.TagIdParseErrors.WithLabelValues("not_string").Inc()
 		return 0, errors.New("TagId value is not a string")
 	}
 
@@ -89,10 +74,12 @@ func parseTagId(fieldValue FieldValue) (uint32, error) {
 
 	tagId, err := strconv.ParseInt(strVal, 10, 32)
 	if err != nil {
+		metrics.TagIdParseErrors.WithLabelValues("not_an_integer").Inc()
 		return 0, fmt.Errorf("failed to convert string TagId to int: %v", err)
 	}
 
 	if tagId <= 0 {
+		metrics.TagIdParseErrors.WithLabelValues("non_positive").Inc()
 		return 0, errors.New("TagId value is non-positive")
 	}
 
@@ -102,6 +89,7 @@ func parseTagId(fieldValue FieldValue) (uint32, error) {
 func parseTrainingLabels(fieldValue FieldValue) ([]string, error) {
 	trainingValues, ok := fieldValue.Value.([]interface{})
 	if !ok {
+		metrics.TagIdParseErrors.WithLabelValues("training_not_slice").Inc()
 		return nil, errors.New("training value is not a slice")
 	}
 
@@ -109,11 +97,13 @@ func parseTrainingLabels(fieldValue FieldValue) ([]string, error) {
 	for _, t := range trainingValues {
 		trainingMap, ok := t.(map[string]interface{})
 		if !ok {
+			metrics.TagIdParseErrors.WithLabelValues("training_item_not_map").Inc()
 			return nil, errors.New("training item is not a map")
 		}
 
 		label, err := extractLabelFromTrainingMap(trainingMap)
 		if err != nil {
+			metrics.TagIdParseErrors.WithLabelValues("training_label_not_string").Inc()
 			return nil, err
 		}
 		labels = append(labels, label)
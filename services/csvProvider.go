@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"rfid-backend/config"
+	"rfid-backend/models"
+)
+
+// CSVProvider fetches membership data from a CSV file served over HTTP, for
+// makerspaces that don't run Wild Apricot. Rows are expected to have a
+// header row including cfg.CSVIdColumn, cfg.CSVTagIdColumn, and
+// cfg.CSVTrainingColumn. cfg.CSVIdColumn must hold a value that uniquely and
+// durably identifies the person the row belongs to (e.g. a member ID or
+// email address) - the tag ID can't be used for this, since it's legitimately
+// blank or shared across rows for members with no fob issued yet.
+type CSVProvider struct {
+	cfg *config.Config
+}
+
+func NewCSVProvider(cfg *config.Config) *CSVProvider {
+	return &CSVProvider{cfg: cfg}
+}
+
+func (p *CSVProvider) GetContacts(ctx context.Context) ([]models.Contact, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.CSVSourceURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build CSV source request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CSV source: %v", err)
+	}
+	defer resp.Body.Close()
+
+	return parseCSVContacts(resp.Body, p.cfg.CSVIdColumn, p.cfg.CSVTagIdColumn, p.cfg.CSVTrainingColumn)
+}
+
+func parseCSVContacts(r io.Reader, idColumn, tagIdColumn, trainingColumn string) ([]models.Contact, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	contacts := make([]models.Contact, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		contact, ok := models.NewContactFromCSVRow(header, row, idColumn, tagIdColumn, trainingColumn)
+		if !ok {
+			slog.Warn("skipping CSV row with no value in id column", "id_column", idColumn)
+			continue
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, nil
+}
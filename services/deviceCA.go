@@ -0,0 +1,236 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// DeviceCA issues and revokes per-device client certificates used for mTLS
+// authentication on the RFID reader endpoints. The CA key is encrypted at
+// rest with a passphrase and only ever decrypted in memory.
+type DeviceCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// GenerateCA creates a new self-signed CA certificate and key for commonName,
+// valid for validFor, and returns the CA certificate and the private key
+// encrypted under password in the format LoadDeviceCA expects.
+func GenerateCA(commonName string, validFor time.Duration, password string) (certPEM, encryptedKeyPEM []byte, err error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA key: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA serial number: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-5 * time.Minute),
+		NotAfter:              time.Now().Add(validFor),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to self-sign CA cert: %v", err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+
+	rawKey, err := x509.MarshalECPrivateKey(caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal CA key: %v", err)
+	}
+
+	encryptedKey, err := encryptCAKey(rawKey, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encrypt CA key: %v", err)
+	}
+	encryptedKeyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: encryptedKey})
+
+	return certPEM, encryptedKeyPEM, nil
+}
+
+// LoadDeviceCA reads a PEM-encoded CA certificate and a passphrase-encrypted
+// PEM-encoded EC private key from disk.
+func LoadDeviceCA(certPath, keyPath, password string) (*DeviceCA, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA cert: %v", err)
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, fmt.Errorf("failed to decode CA cert PEM")
+	}
+
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA cert: %v", err)
+	}
+
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA key: %v", err)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("failed to decode CA key PEM")
+	}
+
+	keyBytes, err := decryptCAKey(keyBlock.Bytes, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt CA key: %v", err)
+	}
+
+	key, err := x509.ParseECPrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA key: %v", err)
+	}
+
+	return &DeviceCA{cert: cert, key: key}, nil
+}
+
+// IssueCertificate mints a client certificate for commonName, valid for
+// validFor, returning the certificate and private key as PEM blocks.
+func (ca *DeviceCA) IssueCertificate(commonName string, validFor time.Duration) (certPEM, keyPEM []byte, serial *big.Int, err error) {
+	deviceKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate device key: %v", err)
+	}
+
+	serial, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate serial number: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-5 * time.Minute),
+		NotAfter:     time.Now().Add(validFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &deviceKey.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to sign device cert: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+
+	rawKey, err := x509.MarshalECPrivateKey(deviceKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal device key: %v", err)
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey})
+
+	return certPEM, keyPEM, serial, nil
+}
+
+// BuildCRL signs a certificate revocation list containing revokedSerials.
+func (ca *DeviceCA) BuildCRL(revokedSerials []pkix.RevokedCertificate) ([]byte, error) {
+	template := &x509.RevocationList{
+		Number:     big.NewInt(time.Now().Unix()),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(24 * time.Hour),
+		RevokedCertificateEntries: toRevocationEntries(revokedSerials),
+	}
+
+	return x509.CreateRevocationList(rand.Reader, template, ca.cert, ca.key)
+}
+
+func toRevocationEntries(revoked []pkix.RevokedCertificate) []x509.RevocationListEntry {
+	entries := make([]x509.RevocationListEntry, len(revoked))
+	for i, r := range revoked {
+		entries[i] = x509.RevocationListEntry{
+			SerialNumber:   r.SerialNumber,
+			RevocationTime: r.RevocationTime,
+		}
+	}
+	return entries
+}
+
+// encryptCAKey derives a key from password via PBKDF2 and encrypts rawKey
+// with AES-GCM, producing the salt||nonce||ciphertext blob decryptCAKey
+// expects. Used by GenerateCA (exposed via cmd/initca) to bootstrap a new CA.
+func encryptCAKey(rawKey []byte, password string) ([]byte, error) {
+	const saltLen = 16
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	derivedKey := pbkdf2.Key([]byte(password), salt, 100000, 32, sha256.New)
+
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, rawKey, nil)
+	return append(salt, ciphertext...), nil
+}
+
+// decryptCAKey derives a key from password via PBKDF2 and decrypts the CA
+// private key. The on-disk format is a salt||nonce||ciphertext blob produced
+// by encryptCAKey; see cmd/initca for the command that bootstraps a new CA
+// using it.
+func decryptCAKey(blob []byte, password string) ([]byte, error) {
+	const saltLen = 16
+	if len(blob) < saltLen {
+		return nil, fmt.Errorf("CA key blob too short")
+	}
+
+	salt := blob[:saltLen]
+	ciphertext := blob[saltLen:]
+	derivedKey := pbkdf2.Key([]byte(password), salt, 100000, 32, sha256.New)
+
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("CA key ciphertext too short")
+	}
+
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateCAIssueAndLoadRoundTrip(t *testing.T) {
+	certPEM, keyPEM, err := GenerateCA("Test CA", 24*time.Hour, "s3cret")
+	if err != nil {
+		t.Fatalf("GenerateCA() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "ca.crt")
+	keyPath := filepath.Join(dir, "ca.key")
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		t.Fatalf("failed to write CA cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		t.Fatalf("failed to write CA key: %v", err)
+	}
+
+	if _, err := LoadDeviceCA(certPath, keyPath, "wrong password"); err == nil {
+		t.Fatal("LoadDeviceCA() with wrong password: expected error, got nil")
+	}
+
+	ca, err := LoadDeviceCA(certPath, keyPath, "s3cret")
+	if err != nil {
+		t.Fatalf("LoadDeviceCA() error = %v", err)
+	}
+
+	certPEMOut, keyPEMOut, serial, err := ca.IssueCertificate("reader-01", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueCertificate() error = %v", err)
+	}
+	if len(certPEMOut) == 0 || len(keyPEMOut) == 0 {
+		t.Fatal("IssueCertificate() returned empty cert or key PEM")
+	}
+
+	deviceBlock, _ := pem.Decode(certPEMOut)
+	deviceCert, err := x509.ParseCertificate(deviceBlock.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse issued device cert: %v", err)
+	}
+	if deviceCert.Subject.CommonName != "reader-01" {
+		t.Errorf("issued cert CommonName = %q, want %q", deviceCert.Subject.CommonName, "reader-01")
+	}
+	if serial.Sign() <= 0 {
+		t.Errorf("issued cert serial = %v, want a positive number", serial)
+	}
+
+	caBlock, _ := pem.Decode(certPEM)
+	caCert, err := x509.ParseCertificate(caBlock.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse CA cert: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	if _, err := deviceCert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Errorf("issued device cert did not verify against the CA: %v", err)
+	}
+}
+
+func TestBuildCRLIncludesRevokedSerial(t *testing.T) {
+	certPEM, keyPEM, err := GenerateCA("Test CA", 24*time.Hour, "s3cret")
+	if err != nil {
+		t.Fatalf("GenerateCA() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "ca.crt")
+	keyPath := filepath.Join(dir, "ca.key")
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		t.Fatalf("failed to write CA cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		t.Fatalf("failed to write CA key: %v", err)
+	}
+
+	ca, err := LoadDeviceCA(certPath, keyPath, "s3cret")
+	if err != nil {
+		t.Fatalf("LoadDeviceCA() error = %v", err)
+	}
+
+	_, _, serial, err := ca.IssueCertificate("reader-02", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueCertificate() error = %v", err)
+	}
+
+	crlDER, err := ca.BuildCRL([]pkix.RevokedCertificate{{SerialNumber: serial, RevocationTime: time.Now()}})
+	if err != nil {
+		t.Fatalf("BuildCRL() error = %v", err)
+	}
+
+	crl, err := x509.ParseRevocationList(crlDER)
+	if err != nil {
+		t.Fatalf("failed to parse generated CRL: %v", err)
+	}
+	if len(crl.RevokedCertificateEntries) != 1 {
+		t.Fatalf("CRL has %d revoked entries, want 1", len(crl.RevokedCertificateEntries))
+	}
+	if crl.RevokedCertificateEntries[0].SerialNumber.Cmp(serial) != 0 {
+		t.Errorf("CRL revoked serial = %v, want %v", crl.RevokedCertificateEntries[0].SerialNumber, serial)
+	}
+}
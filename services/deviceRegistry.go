@@ -0,0 +1,90 @@
+package services
+
+import (
+	"crypto/x509/pkix"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// DeviceRegistry tracks issued client certificates for RFID readers, backing
+// the mTLS middleware's CN-to-device lookup and CRL generation.
+type DeviceRegistry struct {
+	db *sql.DB
+}
+
+func NewDeviceRegistry(db *sql.DB) *DeviceRegistry {
+	return &DeviceRegistry{db: db}
+}
+
+// RegisterDeviceCert records a newly issued certificate against the device's
+// existing row (created by the registerDevice flow) so the mTLS middleware
+// can map a verified CN back to a known reader.
+func (r *DeviceRegistry) RegisterDeviceCert(cn string, serial *big.Int, notAfter time.Time) error {
+	_, err := r.db.Exec(
+		`UPDATE devices SET cert_serial = ?, cert_not_after = ? WHERE name = ?`,
+		serial.String(), notAfter, cn,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record device cert for %q: %v", cn, err)
+	}
+	return nil
+}
+
+// IsRegistered reports whether cn matches a known, non-revoked device.
+func (r *DeviceRegistry) IsRegistered(cn string) (bool, error) {
+	var revokedAt sql.NullTime
+	err := r.db.QueryRow(`SELECT revoked_at FROM devices WHERE name = ?`, cn).Scan(&revokedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up device %q: %v", cn, err)
+	}
+	return !revokedAt.Valid, nil
+}
+
+// RevokeDevice marks a device's certificate revoked so it's included in the
+// next CRL generated by RevokedCertificates.
+func (r *DeviceRegistry) RevokeDevice(cn string) error {
+	_, err := r.db.Exec(`UPDATE devices SET revoked_at = ? WHERE name = ?`, time.Now(), cn)
+	if err != nil {
+		return fmt.Errorf("failed to revoke device %q: %v", cn, err)
+	}
+	return nil
+}
+
+// RevokedCertificates returns the revocation list entries for all devices
+// marked revoked that were also issued a certificate, for use with
+// DeviceCA.BuildCRL. A device can be revoked before it's ever had a cert
+// issued (cert_serial NULL); such rows have nothing to put on a CRL and are
+// skipped rather than included with a zero serial.
+func (r *DeviceRegistry) RevokedCertificates() ([]pkix.RevokedCertificate, error) {
+	rows, err := r.db.Query(`SELECT cert_serial, revoked_at FROM devices WHERE revoked_at IS NOT NULL AND cert_serial IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revoked devices: %v", err)
+	}
+	defer rows.Close()
+
+	var revoked []pkix.RevokedCertificate
+	for rows.Next() {
+		var serialStr string
+		var revokedAt time.Time
+		if err := rows.Scan(&serialStr, &revokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revoked device: %v", err)
+		}
+
+		serial, ok := new(big.Int).SetString(serialStr, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid stored serial %q", serialStr)
+		}
+
+		revoked = append(revoked, pkix.RevokedCertificate{
+			SerialNumber:   serial,
+			RevocationTime: revokedAt,
+		})
+	}
+
+	return revoked, rows.Err()
+}
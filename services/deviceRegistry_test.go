@@ -0,0 +1,108 @@
+package services
+
+import (
+	"database/sql"
+	"math/big"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestDeviceRegistry(t *testing.T) *DeviceRegistry {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// Mirrors the devices table the registerDevice flow creates, plus the
+	// cert-tracking columns added by db/schema/002_device_certs.sql.
+	const schema = `
+		CREATE TABLE devices (
+			name TEXT PRIMARY KEY,
+			cert_serial TEXT,
+			cert_not_after DATETIME,
+			revoked_at DATETIME
+		)`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create devices table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO devices (name) VALUES (?)`, "front-door"); err != nil {
+		t.Fatalf("failed to seed devices table: %v", err)
+	}
+
+	return NewDeviceRegistry(db)
+}
+
+func TestIsRegisteredUnknownDevice(t *testing.T) {
+	registry := newTestDeviceRegistry(t)
+
+	registered, err := registry.IsRegistered("does-not-exist")
+	if err != nil {
+		t.Fatalf("IsRegistered() error = %v", err)
+	}
+	if registered {
+		t.Error("IsRegistered() = true for an unknown device, want false")
+	}
+}
+
+func TestIsRegisteredKnownDevice(t *testing.T) {
+	registry := newTestDeviceRegistry(t)
+
+	registered, err := registry.IsRegistered("front-door")
+	if err != nil {
+		t.Fatalf("IsRegistered() error = %v", err)
+	}
+	if !registered {
+		t.Error("IsRegistered() = false for a known, non-revoked device, want true")
+	}
+}
+
+func TestRevokeDeviceMakesItUnregistered(t *testing.T) {
+	registry := newTestDeviceRegistry(t)
+
+	if err := registry.RevokeDevice("front-door"); err != nil {
+		t.Fatalf("RevokeDevice() error = %v", err)
+	}
+
+	registered, err := registry.IsRegistered("front-door")
+	if err != nil {
+		t.Fatalf("IsRegistered() error = %v", err)
+	}
+	if registered {
+		t.Error("IsRegistered() = true after RevokeDevice(), want false")
+	}
+
+	revoked, err := registry.RevokedCertificates()
+	if err != nil {
+		t.Fatalf("RevokedCertificates() error = %v", err)
+	}
+	if len(revoked) != 0 {
+		t.Errorf("RevokedCertificates() = %d entries, want 0 for a device with no issued cert_serial", len(revoked))
+	}
+}
+
+func TestRevokedCertificatesIncludesIssuedSerial(t *testing.T) {
+	registry := newTestDeviceRegistry(t)
+
+	if err := registry.RegisterDeviceCert("front-door", big.NewInt(12345), time.Now().Add(365*24*time.Hour)); err != nil {
+		t.Fatalf("RegisterDeviceCert() error = %v", err)
+	}
+	if err := registry.RevokeDevice("front-door"); err != nil {
+		t.Fatalf("RevokeDevice() error = %v", err)
+	}
+
+	revoked, err := registry.RevokedCertificates()
+	if err != nil {
+		t.Fatalf("RevokedCertificates() error = %v", err)
+	}
+	if len(revoked) != 1 {
+		t.Fatalf("RevokedCertificates() = %d entries, want 1", len(revoked))
+	}
+	if revoked[0].SerialNumber.String() != "12345" {
+		t.Errorf("RevokedCertificates()[0].SerialNumber = %s, want 12345", revoked[0].SerialNumber.String())
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"rfid-backend/config"
+	"rfid-backend/models"
+)
+
+// LDAPProvider fetches membership data from a directory server, mapping a
+// configurable tag attribute (e.g. employeeNumber) and a multi-valued
+// training-group attribute onto the same Contact shape Wild Apricot
+// produces, via LDAPMapper.
+type LDAPProvider struct {
+	cfg *config.Config
+}
+
+func NewLDAPProvider(cfg *config.Config) *LDAPProvider {
+	return &LDAPProvider{cfg: cfg}
+}
+
+func (p *LDAPProvider) GetContacts(ctx context.Context) ([]models.Contact, error) {
+	conn, err := ldap.DialURL(p.cfg.LDAPURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(p.cfg.LDAPBindDN, p.cfg.LDAPBindPassword); err != nil {
+		return nil, fmt.Errorf("failed to bind to LDAP server: %v", err)
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		p.cfg.LDAPBaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=person)",
+		[]string{"cn", "mail", "entryUUID", p.cfg.LDAPTagAttribute, p.cfg.LDAPTrainingAttribute},
+		nil,
+	)
+
+	result, err := conn.SearchWithPaging(searchRequest, 100)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search LDAP directory: %v", err)
+	}
+
+	contacts := make([]models.Contact, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		contacts = append(contacts, models.NewContactFromLDAPEntry(
+			entry.GetAttributeValue("entryUUID"),
+			entry.DN,
+			entry.GetAttributeValue("cn"),
+			entry.GetAttributeValue("mail"),
+			p.cfg.LDAPTagAttribute,
+			entry.GetAttributeValue(p.cfg.LDAPTagAttribute),
+			p.cfg.LDAPTrainingAttribute,
+			entry.GetAttributeValues(p.cfg.LDAPTrainingAttribute),
+		))
+	}
+
+	return contacts, nil
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	"rfid-backend/models"
+)
+
+// MembershipProvider abstracts the source of membership/contact data so the
+// periodic sync loop doesn't need to know whether contacts come from Wild
+// Apricot, an LDAP directory, or a CSV/HTTP-pull feed. The webhook handler
+// does not go through it: webhooks always resync from Wild Apricot.
+type MembershipProvider interface {
+	GetContacts(ctx context.Context) ([]models.Contact, error)
+}
+
+// wildApricotProvider adapts WildApricotService's account-scoped GetContacts
+// to the context-scoped MembershipProvider interface.
+type wildApricotProvider struct {
+	svc       *WildApricotService
+	accountId int
+}
+
+func NewWildApricotProvider(svc *WildApricotService, accountId int) MembershipProvider {
+	return &wildApricotProvider{svc: svc, accountId: accountId}
+}
+
+func (p *wildApricotProvider) GetContacts(ctx context.Context) ([]models.Contact, error) {
+	return p.svc.GetContacts(p.accountId)
+}
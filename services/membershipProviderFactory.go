@@ -0,0 +1,40 @@
+package services
+
+import (
+	"fmt"
+
+	"rfid-backend/config"
+	"rfid-backend/models"
+)
+
+// NewMembershipProvider constructs the MembershipProvider and matching
+// ContactMapper selected by cfg.MembershipProviderType, so dbService always
+// extracts tag IDs and training labels the same way regardless of source.
+func NewMembershipProvider(cfg *config.Config, waSvc *WildApricotService) (MembershipProvider, models.ContactMapper, error) {
+	switch cfg.MembershipProviderType {
+	case "", "wildapricot":
+		provider := NewWildApricotProvider(waSvc, cfg.WildApricotAccountId)
+		mapper := &models.FieldValueMapper{
+			TagIdFieldName:    cfg.TagIdFieldName,
+			TrainingFieldName: cfg.TrainingFieldName,
+		}
+		return provider, mapper, nil
+	case "ldap":
+		provider := NewLDAPProvider(cfg)
+		mapper := &models.LDAPMapper{
+			TagAttribute:        cfg.LDAPTagAttribute,
+			TrainingAttribute:   cfg.LDAPTrainingAttribute,
+			TrainingGroupLabels: cfg.LDAPTrainingGroupLabels,
+		}
+		return provider, mapper, nil
+	case "csv":
+		provider := NewCSVProvider(cfg)
+		mapper := &models.FieldValueMapper{
+			TagIdFieldName:    cfg.CSVTagIdColumn,
+			TrainingFieldName: cfg.CSVTrainingColumn,
+		}
+		return provider, mapper, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown membership provider type %q", cfg.MembershipProviderType)
+	}
+}